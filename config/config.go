@@ -0,0 +1,46 @@
+// Package config defines the configuration for the video-transcoding-api,
+// loaded from environment variables or a config file.
+package config
+
+// Config is the root of the application configuration, grouping the
+// settings for each of the supported providers.
+type Config struct {
+	ElasticTranscoder *ElasticTranscoder
+}
+
+// Pipeline represents one Elastic Transcoder pipeline available to the
+// provider. Jobs are routed to a pipeline by name, by matching input
+// bucket, or by round-robin, so that a single provider instance can fan
+// out across pipelines with different IAM roles, buckets, or priority
+// queues.
+type Pipeline struct {
+	Name          string
+	ID            string
+	InputBucket   string
+	OutputBucket  string
+	Role          string
+	StorageClass  string
+	Notifications bool
+}
+
+// ElasticTranscoder contains the configuration for the Elastic Transcoder
+// provider.
+type ElasticTranscoder struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+
+	// Pipelines is the pool of pipelines this provider instance can route
+	// jobs to. At least one must be configured.
+	Pipelines []Pipeline
+
+	// SQSQueueURL is the URL of the SQS queue subscribed to the pipelines'
+	// SNS notification topics. When set, the provider reports job status
+	// updates as they arrive on this queue instead of being polled.
+	SQSQueueURL string
+
+	// NotificationsWorkerConcurrency is the number of goroutines used to
+	// process messages received from SQSQueueURL concurrently. Defaults
+	// to 1 when unset.
+	NotificationsWorkerConcurrency int
+}
@@ -0,0 +1,47 @@
+// Package db provides types and an interface for persisting jobs and
+// presets used by the video-transcoding-api.
+package db
+
+import "errors"
+
+// ErrJobNotFound is the error returned by a Repository when no job is
+// stored under the requested id.
+var ErrJobNotFound = errors.New("job not found")
+
+// Job represents a transcoding job managed by the API.
+type Job struct {
+	ID            string      `json:"jobId"`
+	ProviderName  string      `json:"providerName"`
+	ProviderJobID string      `json:"providerJobId"`
+	Status        string      `json:"status"`
+	ErrorMessage  string      `json:"errorMessage,omitempty"`
+	Outputs       []JobOutput `json:"outputs,omitempty"`
+}
+
+// JobOutput represents the status of a single output file of a job.
+type JobOutput struct {
+	Key          string `json:"key"`
+	Status       string `json:"status,omitempty"`
+	StatusDetail string `json:"statusDetail,omitempty"`
+}
+
+// Repository is implemented by the storage backends that persist jobs and
+// presets, so that other packages (such as provider/notifications
+// subscribers) can update job state directly.
+type Repository interface {
+	GetJob(id string) (*Job, error)
+	SaveJob(job *Job) error
+}
+
+// OutputOptions contains options that can be customized per output file.
+type OutputOptions struct {
+	Extension string `json:"extension"`
+}
+
+// PresetMap represents the association between a preset name and its
+// corresponding provider-specific preset IDs.
+type PresetMap struct {
+	Name            string            `json:"name"`
+	ProviderMapping map[string]string `json:"providerMapping"`
+	OutputOpts      OutputOptions     `json:"outputOptions"`
+}
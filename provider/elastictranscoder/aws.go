@@ -4,15 +4,15 @@
 // It doesn't expose any public type. In order to use the provider, one must
 // import this package and then grab the factory from the provider package:
 //
-//     import (
-//         "github.com/nytm/video-transcoding-api/provider"
-//         "github.com/nytm/video-transcoding-api/provider/elastictranscoder"
-//     )
+//	import (
+//	    "github.com/nytm/video-transcoding-api/provider"
+//	    "github.com/nytm/video-transcoding-api/provider/elastictranscoder"
+//	)
 //
-//     func UseProvider() {
-//         factory, err := provider.GetProviderFactory(elastictranscoder.Name)
-//         // handle err and use factory to get an instance of the provider.
-//     }
+//	func UseProvider() {
+//	    factory, err := provider.GetProviderFactory(elastictranscoder.Name)
+//	    // handle err and use factory to get an instance of the provider.
+//	}
 package elastictranscoder
 
 import (
@@ -22,6 +22,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -53,13 +55,121 @@ func init() {
 type awsProvider struct {
 	c      elastictranscoderiface.ElasticTranscoderAPI
 	config *config.ElasticTranscoder
+
+	// rrCounter is used to round-robin across config.Pipelines when a job
+	// doesn't specify a pipeline name and its source doesn't match any
+	// pipeline's input bucket.
+	rrCounter uint64
+
+	// pipelinesMu guards config.Pipelines, which CreatePipeline,
+	// UpdatePipeline and DeletePipeline mutate at runtime so that newly
+	// managed pipelines are immediately selectable and health-checked
+	// without a process restart.
+	pipelinesMu sync.RWMutex
+}
+
+// pipelineByName returns the configured pipeline with the given name, or
+// ErrPipelineNotFound.
+func (p *awsProvider) pipelineByName(name string) (*config.Pipeline, error) {
+	p.pipelinesMu.RLock()
+	defer p.pipelinesMu.RUnlock()
+	for i := range p.config.Pipelines {
+		if p.config.Pipelines[i].Name == name {
+			pipeline := p.config.Pipelines[i]
+			return &pipeline, nil
+		}
+	}
+	return nil, provider.ErrPipelineNotFound
+}
+
+// pipelineByInputBucket returns the configured pipeline whose InputBucket
+// matches bucket, if any.
+func (p *awsProvider) pipelineByInputBucket(bucket string) *config.Pipeline {
+	p.pipelinesMu.RLock()
+	defer p.pipelinesMu.RUnlock()
+	for i := range p.config.Pipelines {
+		if bucket != "" && p.config.Pipelines[i].InputBucket == bucket {
+			pipeline := p.config.Pipelines[i]
+			return &pipeline
+		}
+	}
+	return nil
+}
+
+// nextPipeline round-robins across config.Pipelines.
+func (p *awsProvider) nextPipeline() (*config.Pipeline, error) {
+	p.pipelinesMu.RLock()
+	defer p.pipelinesMu.RUnlock()
+	if len(p.config.Pipelines) == 0 {
+		return nil, errors.New("elastictranscoder: no pipelines configured")
+	}
+	i := atomic.AddUint64(&p.rrCounter, 1) % uint64(len(p.config.Pipelines))
+	pipeline := p.config.Pipelines[i]
+	return &pipeline, nil
+}
+
+// allPipelines returns a snapshot of the configured pipelines, safe to
+// range over without holding pipelinesMu.
+func (p *awsProvider) allPipelines() []config.Pipeline {
+	p.pipelinesMu.RLock()
+	defer p.pipelinesMu.RUnlock()
+	pipelines := make([]config.Pipeline, len(p.config.Pipelines))
+	copy(pipelines, p.config.Pipelines)
+	return pipelines
+}
+
+// selectPipeline picks the pipeline a job should be submitted to: the
+// explicitly requested one, falling back to a match on the source's input
+// bucket, falling back to round-robin.
+func (p *awsProvider) selectPipeline(transcodeProfile provider.TranscodeProfile) (*config.Pipeline, error) {
+	if transcodeProfile.PipelineName != "" {
+		return p.pipelineByName(transcodeProfile.PipelineName)
+	}
+	if pipeline := p.pipelineByInputBucket(p.inputBucket(transcodeProfile.SourceMedia)); pipeline != nil {
+		return pipeline, nil
+	}
+	return p.nextPipeline()
+}
+
+// inputBucket extracts the S3 bucket name out of an "s3://bucket/key"
+// source, returning "" for non-S3 sources.
+func (p *awsProvider) inputBucket(source string) string {
+	if !s3Pattern.MatchString(source) {
+		return ""
+	}
+	source = strings.Replace(source, "s3://", "", 1)
+	return strings.SplitN(source, "/", 2)[0]
+}
+
+// playlistFormat maps a requested adaptive streaming protocol to the
+// Elastic Transcoder playlist Format value used to generate it.
+var playlistFormat = map[string]string{
+	"hls":    "HLSv3",
+	"hlsv4":  "HLSv4",
+	"smooth": "Smooth",
+	"dash":   "MPEG-DASH",
+}
+
+// playlistContainer maps a requested adaptive streaming protocol to the
+// output container its outputs must use, so outputs can be grouped into
+// the right playlist: HLS plays back "ts" segments, while Smooth and
+// MPEG-DASH play back fragmented MP4 ("fmp4") segments.
+var playlistContainer = map[string]string{
+	"hls":    "ts",
+	"hlsv4":  "ts",
+	"smooth": "fmp4",
+	"dash":   "fmp4",
 }
 
 func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.TranscodeProfile) (*provider.JobStatus, error) {
-	var adaptiveStreamingPresets []db.PresetMap
+	pipeline, err := p.selectPipeline(transcodeProfile)
+	if err != nil {
+		return nil, err
+	}
+	outputsByContainer := make(map[string][]db.PresetMap)
 	source := p.normalizeSource(transcodeProfile.SourceMedia)
 	params := elastictranscoder.CreateJobInput{
-		PipelineId: aws.String(p.config.PipelineID),
+		PipelineId: aws.String(pipeline.ID),
 		Input:      &elastictranscoder.JobInput{Key: aws.String(source)},
 	}
 	params.Outputs = make([]*elastictranscoder.CreateJobOutput, len(transcodeProfile.Presets))
@@ -78,10 +188,10 @@ func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.Transcode
 		if presetOutput.Preset == nil || presetOutput.Preset.Container == nil {
 			return nil, fmt.Errorf("misconfigured preset: %s", presetID)
 		}
-		isAdaptiveStreamingPreset := false
-		if *presetOutput.Preset.Container == "ts" {
-			isAdaptiveStreamingPreset = true
-			adaptiveStreamingPresets = append(adaptiveStreamingPresets, preset)
+		container := *presetOutput.Preset.Container
+		isAdaptiveStreamingPreset := container == "ts" || container == "fmp4"
+		if isAdaptiveStreamingPreset {
+			outputsByContainer[container] = append(outputsByContainer[container], preset)
 		}
 		params.Outputs[i] = &elastictranscoder.CreateJobOutput{
 			PresetId: aws.String(presetID),
@@ -92,19 +202,8 @@ func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.Transcode
 		}
 	}
 
-	if len(adaptiveStreamingPresets) > 0 {
-		jobPlaylist := elastictranscoder.CreateJobPlaylist{
-			Format: aws.String("HLSv3"),
-			Name:   aws.String(job.ID + "/" + strings.TrimRight(source, filepath.Ext(source)) + "/master"),
-		}
+	params.Playlists = p.createPlaylists(job, source, transcodeProfile.StreamingParams, outputsByContainer)
 
-		jobPlaylist.OutputKeys = make([]*string, len(adaptiveStreamingPresets))
-		for i, preset := range adaptiveStreamingPresets {
-			jobPlaylist.OutputKeys[i] = p.outputKey(job, preset.OutputOpts, source, preset.Name, true)
-		}
-
-		params.Playlists = []*elastictranscoder.CreateJobPlaylist{&jobPlaylist}
-	}
 	resp, err := p.c.CreateJob(&params)
 	if err != nil {
 		return nil, err
@@ -116,6 +215,81 @@ func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.Transcode
 	}, nil
 }
 
+// createPlaylists builds one CreateJobPlaylist per protocol requested in
+// streamingParams.Protocols, grouping the job's outputs by the container
+// each protocol plays back. When no protocols are given, it falls back to
+// a single HLS playlist if there are "ts" outputs, matching the provider's
+// historical, HLS-only behavior.
+func (p *awsProvider) createPlaylists(job *db.Job, source string, streamingParams provider.StreamingParams, outputsByContainer map[string][]db.PresetMap) []*elastictranscoder.CreateJobPlaylist {
+	protocols := streamingParams.Protocols
+	if len(protocols) == 0 {
+		protocols = []string{"hls"}
+	}
+	playlistName := streamingParams.PlaylistName
+	if playlistName == "" {
+		playlistName = job.ID + "/" + strings.TrimRight(source, filepath.Ext(source)) + "/master"
+	}
+	var playlists []*elastictranscoder.CreateJobPlaylist
+	for _, protocol := range protocols {
+		presets := outputsByContainer[playlistContainer[protocol]]
+		if len(presets) == 0 {
+			continue
+		}
+		name := playlistName
+		if len(protocols) > 1 {
+			// CreateJob requires playlist names to be unique within a job.
+			// A single job can request several protocols side-by-side
+			// (e.g. dash and smooth both play back "fmp4" outputs), so the
+			// shared base name must be disambiguated per protocol.
+			name = playlistName + "-" + protocol
+		}
+		jobPlaylist := &elastictranscoder.CreateJobPlaylist{
+			Format:     aws.String(playlistFormat[protocol]),
+			Name:       aws.String(name),
+			OutputKeys: make([]*string, len(presets)),
+		}
+		for i, preset := range presets {
+			jobPlaylist.OutputKeys[i] = p.outputKey(job, preset.OutputOpts, source, preset.Name, true)
+		}
+		switch protocol {
+		case "hls", "hlsv4":
+			jobPlaylist.HlsContentProtection = p.createHLSContentProtection(streamingParams.HLSContentProtection)
+		case "dash", "smooth":
+			jobPlaylist.PlayReadyDrm = p.createPlayReadyDRM(streamingParams.PlayReadyDRM)
+		}
+		playlists = append(playlists, jobPlaylist)
+	}
+	return playlists
+}
+
+func (p *awsProvider) createHLSContentProtection(protection *provider.HLSContentProtection) *elastictranscoder.HlsContentProtection {
+	if protection == nil {
+		return nil
+	}
+	return &elastictranscoder.HlsContentProtection{
+		Method:                stringOrDefault(protection.Method, "aes-128"),
+		Key:                   aws.String(protection.Key),
+		KeyMd5:                aws.String(protection.KeyMd5),
+		InitializationVector:  aws.String(protection.InitializationVector),
+		LicenseAcquisitionUrl: aws.String(protection.LicenseAcquisitionURL),
+		KeyStoragePolicy:      aws.String(protection.KeyStoragePolicy),
+	}
+}
+
+func (p *awsProvider) createPlayReadyDRM(drm *provider.PlayReadyDRM) *elastictranscoder.PlayReadyDrm {
+	if drm == nil {
+		return nil
+	}
+	return &elastictranscoder.PlayReadyDrm{
+		Format:                stringOrDefault(drm.Format, "microsoft"),
+		Key:                   aws.String(drm.Key),
+		KeyId:                 aws.String(drm.KeyID),
+		KeyMd5:                aws.String(drm.KeyMd5),
+		LicenseAcquisitionUrl: aws.String(drm.LicenseAcquisitionURL),
+		InitializationVector:  aws.String(drm.InitializationVector),
+	}
+}
+
 func (p *awsProvider) normalizeSource(source string) string {
 	if s3Pattern.MatchString(source) {
 		source = strings.Replace(source, "s3://", "", 1)
@@ -139,7 +313,37 @@ func (p *awsProvider) outputKey(job *db.Job, opts db.OutputOptions, source, pres
 	return aws.String(strings.Join(parts, "/"))
 }
 
+// stringOrDefault returns a pointer to value, or to fallback when value is
+// the empty string, so that callers don't have to sprinkle zero-value
+// checks everywhere they build an optional AWS parameter.
+func stringOrDefault(value, fallback string) *string {
+	if value == "" {
+		return aws.String(fallback)
+	}
+	return &value
+}
+
 func (p *awsProvider) createVideoPreset(preset provider.Preset) *elastictranscoder.VideoParameters {
+	codecOptions := map[string]*string{
+		"Profile":            aws.String(strings.ToLower(preset.Profile)),
+		"Level":              &preset.ProfileLevel,
+		"MaxReferenceFrames": stringOrDefault(preset.Video.MaxReferenceFrames, "2"),
+	}
+	if preset.Video.MaxBitRate != "" {
+		codecOptions["MaxBitRate"] = &preset.Video.MaxBitRate
+	}
+	if preset.Video.BufferSize != "" {
+		codecOptions["BufferSize"] = &preset.Video.BufferSize
+	}
+	if preset.Video.InterlacedMode != "" {
+		codecOptions["InterlacedMode"] = &preset.Video.InterlacedMode
+	}
+	if preset.Video.ColorSpaceConversion != "" {
+		codecOptions["ColorSpaceConversion"] = &preset.Video.ColorSpaceConversion
+	}
+	for option, value := range preset.Video.CodecOptions {
+		codecOptions[option] = aws.String(value)
+	}
 	videoPreset := elastictranscoder.VideoParameters{
 		DisplayAspectRatio: aws.String("auto"),
 		FrameRate:          aws.String("auto"),
@@ -147,21 +351,10 @@ func (p *awsProvider) createVideoPreset(preset provider.Preset) *elastictranscod
 		PaddingPolicy:      aws.String("Pad"),
 		Codec:              &preset.Video.Codec,
 		KeyframesMaxDist:   &preset.Video.GopSize,
-		CodecOptions: map[string]*string{
-			"Profile":            aws.String(strings.ToLower(preset.Profile)),
-			"Level":              &preset.ProfileLevel,
-			"MaxReferenceFrames": aws.String("2"),
-		},
-	}
-	if preset.Video.Width != "" {
-		videoPreset.MaxWidth = &preset.Video.Width
-	} else {
-		videoPreset.MaxWidth = aws.String("auto")
-	}
-	if preset.Video.Height != "" {
-		videoPreset.MaxHeight = &preset.Video.Height
-	} else {
-		videoPreset.MaxHeight = aws.String("auto")
+		CodecOptions:       codecOptions,
+		MaxWidth:           stringOrDefault(preset.Video.Width, "auto"),
+		MaxHeight:          stringOrDefault(preset.Video.Height, "auto"),
+		Watermarks:         p.createWatermarks(preset.Watermarks),
 	}
 	normalizedVideoBitRate, _ := strconv.Atoi(preset.Video.Bitrate)
 	videoBitrate := strconv.Itoa(normalizedVideoBitRate / 1000)
@@ -175,14 +368,40 @@ func (p *awsProvider) createVideoPreset(preset provider.Preset) *elastictranscod
 	return &videoPreset
 }
 
+func (p *awsProvider) createWatermarks(watermarks []provider.Watermark) []*elastictranscoder.PresetWatermark {
+	if len(watermarks) == 0 {
+		return nil
+	}
+	result := make([]*elastictranscoder.PresetWatermark, len(watermarks))
+	for i, watermark := range watermarks {
+		result[i] = &elastictranscoder.PresetWatermark{
+			Id:               aws.String(watermark.ID),
+			MaxWidth:         stringOrDefault(watermark.MaxWidth, "10%"),
+			MaxHeight:        stringOrDefault(watermark.MaxHeight, "10%"),
+			SizingPolicy:     aws.String("ShrinkToFit"),
+			HorizontalAlign:  stringOrDefault(watermark.HorizontalAlign, "Right"),
+			HorizontalOffset: stringOrDefault(watermark.HorizontalOffset, "10px"),
+			VerticalAlign:    stringOrDefault(watermark.VerticalAlign, "Bottom"),
+			VerticalOffset:   stringOrDefault(watermark.VerticalOffset, "10px"),
+			Opacity:          stringOrDefault(watermark.Opacity, "100"),
+			Target:           stringOrDefault(watermark.Target, "Content"),
+		}
+	}
+	return result
+}
+
 func (p *awsProvider) createThumbsPreset(preset provider.Preset) *elastictranscoder.Thumbnails {
 	thumbsPreset := &elastictranscoder.Thumbnails{
-		PaddingPolicy: aws.String("Pad"),
-		Format:        aws.String("png"),
-		Interval:      aws.String("1"),
-		SizingPolicy:  aws.String("Fill"),
+		PaddingPolicy: stringOrDefault(preset.Thumbnails.PaddingPolicy, "Pad"),
+		Format:        stringOrDefault(preset.Thumbnails.Format, "png"),
+		Interval:      stringOrDefault(preset.Thumbnails.Interval, "1"),
+		SizingPolicy:  stringOrDefault(preset.Thumbnails.SizingPolicy, "Fill"),
 		MaxWidth:      aws.String("auto"),
 		MaxHeight:     aws.String("auto"),
+		Encryption:    p.createEncryption(preset.Encryption),
+	}
+	if preset.Thumbnails.Resolution != "" {
+		thumbsPreset.Resolution = &preset.Thumbnails.Resolution
 	}
 	return thumbsPreset
 }
@@ -198,13 +417,70 @@ func (p *awsProvider) createAudioPreset(preset provider.Preset) *elastictranscod
 	audioBitrate := strconv.Itoa(normalizedAudioBitRate / 1000)
 	audioPreset.BitRate = &audioBitrate
 
-	if preset.Audio.Codec == "aac" {
+	// AudioCodecOptions takes different fields depending on the codec:
+	// AAC only accepts Profile, while the PCM/FLAC family only accepts
+	// BitDepth/BitOrder/Signed.
+	switch preset.Audio.Codec {
+	case "aac":
 		audioPreset.Codec = aws.String("AAC")
+		audioPreset.CodecOptions = &elastictranscoder.AudioCodecOptions{
+			Profile: stringOrDefault(preset.Audio.Profile, "auto"),
+		}
+	case "pcm", "flac":
+		codecOptions := &elastictranscoder.AudioCodecOptions{}
+		if preset.Audio.BitDepth != "" {
+			codecOptions.BitDepth = &preset.Audio.BitDepth
+		}
+		if preset.Audio.BitOrder != "" {
+			codecOptions.BitOrder = &preset.Audio.BitOrder
+		}
+		if preset.Audio.Signed != "" {
+			codecOptions.Signed = &preset.Audio.Signed
+		}
+		audioPreset.CodecOptions = codecOptions
 	}
 
 	return audioPreset
 }
 
+func (p *awsProvider) createCaptions(captions *provider.Captions) *elastictranscoder.Captions {
+	if captions == nil {
+		return nil
+	}
+	result := &elastictranscoder.Captions{
+		MergePolicy: stringOrDefault(captions.MergePolicy, "MergeOverride"),
+	}
+	for _, source := range captions.CaptionSources {
+		result.CaptionSources = append(result.CaptionSources, &elastictranscoder.CaptionSource{
+			Key:        aws.String(source.Key),
+			Language:   aws.String(source.Language),
+			TimeOffset: aws.String(source.TimeOffset),
+			Label:      aws.String(source.Label),
+			Encryption: p.createEncryption(source.Encryption),
+		})
+	}
+	for _, format := range captions.CaptionFormats {
+		result.CaptionFormats = append(result.CaptionFormats, &elastictranscoder.CaptionFormat{
+			Format:     aws.String(format.Format),
+			Pattern:    aws.String(format.Pattern),
+			Encryption: p.createEncryption(format.Encryption),
+		})
+	}
+	return result
+}
+
+func (p *awsProvider) createEncryption(encryption *provider.Encryption) *elastictranscoder.Encryption {
+	if encryption == nil {
+		return nil
+	}
+	return &elastictranscoder.Encryption{
+		Mode:                 stringOrDefault(encryption.Mode, "aes-cbc-pkcs7"),
+		Key:                  aws.String(encryption.Key),
+		KeyMd5:               aws.String(encryption.KeyMd5),
+		InitializationVector: aws.String(encryption.InitializationVector),
+	}
+}
+
 func (p *awsProvider) CreatePreset(preset provider.Preset) (string, error) {
 	presetInput := elastictranscoder.CreatePresetInput{
 		Name:        &preset.Name,
@@ -218,6 +494,7 @@ func (p *awsProvider) CreatePreset(preset provider.Preset) (string, error) {
 	presetInput.Video = p.createVideoPreset(preset)
 	presetInput.Audio = p.createAudioPreset(preset)
 	presetInput.Thumbnails = p.createThumbsPreset(preset)
+	presetInput.Captions = p.createCaptions(preset.Captions)
 	presetOutput, err := p.c.CreatePreset(&presetInput)
 	if err != nil {
 		return "", err
@@ -244,6 +521,18 @@ func (p *awsProvider) DeletePreset(presetID string) error {
 	return err
 }
 
+func (p *awsProvider) CancelJob(id string) error {
+	resp, err := p.c.ReadJob(&elastictranscoder.ReadJobInput{Id: aws.String(id)})
+	if err != nil {
+		return err
+	}
+	if aws.StringValue(resp.Job.Status) != "Submitted" {
+		return provider.ErrJobNotCancellable
+	}
+	_, err = p.c.CancelJob(&elastictranscoder.CancelJobInput{Id: aws.String(id)})
+	return err
+}
+
 func (p *awsProvider) JobStatus(id string) (*provider.JobStatus, error) {
 	resp, err := p.c.ReadJob(&elastictranscoder.ReadJobInput{Id: aws.String(id)})
 	if err != nil {
@@ -302,10 +591,20 @@ func (p *awsProvider) statusMap(awsStatus string) provider.Status {
 }
 
 func (p *awsProvider) Healthcheck() error {
-	_, err := p.c.ReadPipeline(&elastictranscoder.ReadPipelineInput{
-		Id: aws.String(p.config.PipelineID),
-	})
-	return err
+	for _, pipeline := range p.allPipelines() {
+		_, err := p.c.ReadPipeline(&elastictranscoder.ReadPipelineInput{
+			Id: aws.String(pipeline.ID),
+		})
+		if err != nil {
+			return err
+		}
+		if pipeline.Notifications && p.config.SQSQueueURL != "" {
+			if err := p.verifyNotificationTopics(pipeline.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (p *awsProvider) Capabilities() provider.Capabilities {
@@ -316,8 +615,99 @@ func (p *awsProvider) Capabilities() provider.Capabilities {
 	}
 }
 
+// pipelineOutputConfig builds the PipelineOutputConfig shared by a
+// pipeline's ContentConfig and ThumbnailConfig. Elastic Transcoder rejects
+// CreatePipeline/UpdatePipeline calls that set OutputBucket together with
+// ContentConfig, and requires ThumbnailConfig whenever ContentConfig is
+// set, so every pipeline write goes through ContentConfig+ThumbnailConfig
+// rather than the legacy OutputBucket field.
+func pipelineOutputConfig(spec provider.PipelineSpec) *elastictranscoder.PipelineOutputConfig {
+	return &elastictranscoder.PipelineOutputConfig{
+		Bucket:       aws.String(spec.OutputBucket),
+		StorageClass: stringOrDefault(spec.StorageClass, "Standard"),
+	}
+}
+
+func (p *awsProvider) CreatePipeline(spec provider.PipelineSpec) (string, error) {
+	input := &elastictranscoder.CreatePipelineInput{
+		Name:            aws.String(spec.Name),
+		InputBucket:     aws.String(spec.InputBucket),
+		Role:            aws.String(spec.Role),
+		ContentConfig:   pipelineOutputConfig(spec),
+		ThumbnailConfig: pipelineOutputConfig(spec),
+	}
+	output, err := p.c.CreatePipeline(input)
+	if err != nil {
+		return "", err
+	}
+	id := aws.StringValue(output.Pipeline.Id)
+
+	p.pipelinesMu.Lock()
+	p.config.Pipelines = append(p.config.Pipelines, config.Pipeline{
+		Name:          spec.Name,
+		ID:            id,
+		InputBucket:   spec.InputBucket,
+		OutputBucket:  spec.OutputBucket,
+		Role:          spec.Role,
+		StorageClass:  spec.StorageClass,
+		Notifications: spec.Notifications,
+	})
+	p.pipelinesMu.Unlock()
+
+	return id, nil
+}
+
+func (p *awsProvider) UpdatePipeline(id string, spec provider.PipelineSpec) error {
+	_, err := p.c.UpdatePipeline(&elastictranscoder.UpdatePipelineInput{
+		Id:              aws.String(id),
+		Name:            aws.String(spec.Name),
+		InputBucket:     aws.String(spec.InputBucket),
+		Role:            aws.String(spec.Role),
+		ContentConfig:   pipelineOutputConfig(spec),
+		ThumbnailConfig: pipelineOutputConfig(spec),
+	})
+	if err != nil {
+		return err
+	}
+
+	p.pipelinesMu.Lock()
+	defer p.pipelinesMu.Unlock()
+	for i := range p.config.Pipelines {
+		if p.config.Pipelines[i].ID == id {
+			p.config.Pipelines[i] = config.Pipeline{
+				Name:          spec.Name,
+				ID:            id,
+				InputBucket:   spec.InputBucket,
+				OutputBucket:  spec.OutputBucket,
+				Role:          spec.Role,
+				StorageClass:  spec.StorageClass,
+				Notifications: spec.Notifications,
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (p *awsProvider) DeletePipeline(id string) error {
+	_, err := p.c.DeletePipeline(&elastictranscoder.DeletePipelineInput{Id: aws.String(id)})
+	if err != nil {
+		return err
+	}
+
+	p.pipelinesMu.Lock()
+	defer p.pipelinesMu.Unlock()
+	for i := range p.config.Pipelines {
+		if p.config.Pipelines[i].ID == id {
+			p.config.Pipelines = append(p.config.Pipelines[:i], p.config.Pipelines[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 func elasticTranscoderProvider(cfg *config.Config) (provider.TranscodingProvider, error) {
-	if cfg.ElasticTranscoder.AccessKeyID == "" || cfg.ElasticTranscoder.SecretAccessKey == "" || cfg.ElasticTranscoder.PipelineID == "" {
+	if cfg.ElasticTranscoder.AccessKeyID == "" || cfg.ElasticTranscoder.SecretAccessKey == "" || len(cfg.ElasticTranscoder.Pipelines) == 0 {
 		return nil, errAWSInvalidConfig
 	}
 	creds := credentials.NewStaticCredentials(cfg.ElasticTranscoder.AccessKeyID, cfg.ElasticTranscoder.SecretAccessKey, "")
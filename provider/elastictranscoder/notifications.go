@@ -0,0 +1,336 @@
+package elastictranscoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/elastictranscoder"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+	"github.com/nytm/video-transcoding-api/provider/notifications"
+)
+
+const (
+	defaultNotificationsConcurrency = 1
+
+	// dedupTTL is how long a processed SNS MessageId is remembered for.
+	// Elastic Transcoder notifications don't arrive meaningfully later
+	// than this, so entries older than the TTL are safe to evict,
+	// bounding the dedup set's memory on a long-running worker.
+	dedupTTL = 1 * time.Hour
+
+	// dedupSweepInterval is how often expired dedup entries are evicted.
+	dedupSweepInterval = 5 * time.Minute
+
+	// receiveErrorBackoff is how long a worker waits before retrying
+	// ReceiveMessage after an error, so a persistent failure (bad
+	// credentials, deleted queue) doesn't spin the worker in a hot loop
+	// that pegs CPU and floods the logs.
+	receiveErrorBackoff = 5 * time.Second
+)
+
+// NewNotificationSubscriber builds a notifications.Subscriber that consumes
+// Elastic Transcoder job status updates from the SQS queue configured via
+// cfg.SQSQueueURL and applies them to repo, removing the need to poll
+// JobStatus for jobs submitted to pipelines with notifications enabled.
+func NewNotificationSubscriber(cfg *config.ElasticTranscoder, repo db.Repository) (notifications.Subscriber, error) {
+	if cfg.SQSQueueURL == "" {
+		return nil, errors.New("elastictranscoder: SQSQueueURL is not configured")
+	}
+	creds := credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	region := cfg.Region
+	if region == "" {
+		region = defaultAWSRegion
+	}
+	awsSession := session.New(aws.NewConfig().WithCredentials(creds).WithRegion(region))
+	return newNotificationSubscriber(sqs.New(awsSession), repo, cfg), nil
+}
+
+// snsEnvelope is the outer message delivered to the SQS queue by the SNS
+// topics configured on the pipeline. The actual Elastic Transcoder
+// notification is JSON-encoded in Message.
+type snsEnvelope struct {
+	MessageId string `json:"MessageId"`
+	Message   string `json:"Message"`
+}
+
+// etNotification is the payload Elastic Transcoder publishes to a
+// pipeline's SNS topics on Progressing, Completed, Warning and Error
+// events.
+type etNotification struct {
+	State          string               `json:"state"`
+	JobID          string               `json:"jobId"`
+	PipelineID     string               `json:"pipelineId"`
+	ErrorCode      int                  `json:"errorCode"`
+	MessageDetails string               `json:"messageDetails"`
+	Outputs        []etNotificationItem `json:"outputs"`
+}
+
+type etNotificationItem struct {
+	Key          string `json:"key"`
+	Status       string `json:"status"`
+	StatusDetail string `json:"statusDetail"`
+}
+
+// notificationSubscriber consumes Elastic Transcoder job status updates
+// from an SQS queue fed by the pipeline's SNS topics, and applies them
+// directly to the job repository. This lets the API stop polling
+// JobStatus for jobs submitted to pipelines with notifications enabled.
+type notificationSubscriber struct {
+	sqsClient   sqsiface.SQSAPI
+	queueURL    string
+	concurrency int
+	repo        db.Repository
+
+	seen   map[string]time.Time
+	seenMu sync.Mutex
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newNotificationSubscriber(sqsClient sqsiface.SQSAPI, repo db.Repository, cfg *config.ElasticTranscoder) *notificationSubscriber {
+	concurrency := cfg.NotificationsWorkerConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultNotificationsConcurrency
+	}
+	return &notificationSubscriber{
+		sqsClient:   sqsClient,
+		queueURL:    cfg.SQSQueueURL,
+		concurrency: concurrency,
+		repo:        repo,
+		seen:        make(map[string]time.Time),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start launches the configured number of workers polling the queue and a
+// background sweeper that evicts expired dedup entries. It blocks until
+// Stop is called.
+func (s *notificationSubscriber) Start() error {
+	s.wg.Add(s.concurrency + 1)
+	go func() {
+		defer s.wg.Done()
+		s.sweepDedup()
+	}()
+	for i := 0; i < s.concurrency; i++ {
+		go func() {
+			defer s.wg.Done()
+			s.worker()
+		}()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// sweepDedup periodically evicts dedup entries older than dedupTTL, so the
+// seen set doesn't grow without bound on a long-running worker.
+func (s *notificationSubscriber) sweepDedup() {
+	ticker := time.NewTicker(dedupSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.seenMu.Lock()
+			for id, seenAt := range s.seen {
+				if now.Sub(seenAt) > dedupTTL {
+					delete(s.seen, id)
+				}
+			}
+			s.seenMu.Unlock()
+		}
+	}
+}
+
+// Stop signals all workers to finish processing in-flight messages and
+// return, and waits for them to do so.
+func (s *notificationSubscriber) Stop() error {
+	close(s.stopCh)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *notificationSubscriber) worker() {
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		resp, err := s.sqsClient.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(10),
+		})
+		if err != nil {
+			log.Printf("elastictranscoder: error receiving notifications: %s", err)
+			select {
+			case <-s.stopCh:
+				return
+			case <-time.After(receiveErrorBackoff):
+			}
+			continue
+		}
+		for _, msg := range resp.Messages {
+			s.handleMessage(msg)
+		}
+	}
+}
+
+func (s *notificationSubscriber) handleMessage(msg *sqs.Message) {
+	defer s.deleteMessage(msg)
+	var envelope snsEnvelope
+	if err := json.Unmarshal([]byte(aws.StringValue(msg.Body)), &envelope); err != nil {
+		log.Printf("elastictranscoder: error decoding SNS envelope: %s", err)
+		return
+	}
+	if s.alreadyProcessed(envelope.MessageId) {
+		return
+	}
+	var notification etNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		log.Printf("elastictranscoder: error decoding job notification: %s", err)
+		return
+	}
+	if err := s.applyNotification(&notification); err != nil {
+		log.Printf("elastictranscoder: error applying notification for job %s: %s", notification.JobID, err)
+	}
+}
+
+// toEvent converts the Elastic Transcoder notification payload into the
+// provider-agnostic notifications.Event applied to the job repository.
+func (n *etNotification) toEvent() notifications.Event {
+	event := notifications.Event{
+		ProviderJobID: n.JobID,
+		ProviderName:  Name,
+		Status:        statusFromNotificationState(n.State),
+		Outputs:       make([]notifications.OutputEvent, len(n.Outputs)),
+	}
+	if n.ErrorCode != 0 || n.MessageDetails != "" {
+		event.ErrorMessage = fmt.Sprintf("%d: %s", n.ErrorCode, n.MessageDetails)
+	}
+	for i, output := range n.Outputs {
+		event.Outputs[i] = notifications.OutputEvent{
+			Key:          output.Key,
+			Status:       output.Status,
+			StatusDetail: output.StatusDetail,
+		}
+	}
+	return event
+}
+
+func (s *notificationSubscriber) applyNotification(n *etNotification) error {
+	job, err := s.repo.GetJob(n.JobID)
+	if err != nil {
+		return err
+	}
+	event := n.toEvent()
+	if isTerminalStatus(provider.Status(job.Status)) {
+		// Standard (non-FIFO) SQS only guarantees at-least-once, not
+		// ordered, delivery, and with NotificationsWorkerConcurrency > 1
+		// workers can process messages out of order. Once a job has
+		// reached a terminal state (Completed/Error/Canceled), no later
+		// notification - including a stray or late-delivered Warning -
+		// may change it.
+		return nil
+	}
+	job.Status = string(event.Status)
+	if event.ErrorMessage != "" {
+		job.ErrorMessage = event.ErrorMessage
+	}
+	job.Outputs = make([]db.JobOutput, len(event.Outputs))
+	for i, output := range event.Outputs {
+		job.Outputs[i] = db.JobOutput{Key: output.Key, Status: output.Status, StatusDetail: output.StatusDetail}
+	}
+	return s.repo.SaveJob(job)
+}
+
+func statusFromNotificationState(state string) provider.Status {
+	switch state {
+	case "PROGRESSING":
+		return provider.StatusStarted
+	case "COMPLETED":
+		return provider.StatusFinished
+	case "ERROR":
+		return provider.StatusFailed
+	case "WARNING":
+		// A Warning notification is non-fatal: the job keeps
+		// transcoding and usually still reaches Completed, so it must
+		// not be classified as a terminal, failed status. Its message
+		// is still recorded via Event.ErrorMessage.
+		return provider.StatusStarted
+	default:
+		return provider.StatusStarted
+	}
+}
+
+// isTerminalStatus reports whether status is one a job doesn't transition
+// out of once reached.
+func isTerminalStatus(status provider.Status) bool {
+	switch status {
+	case provider.StatusFinished, provider.StatusFailed, provider.StatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *notificationSubscriber) alreadyProcessed(messageID string) bool {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	if _, ok := s.seen[messageID]; ok {
+		return true
+	}
+	s.seen[messageID] = time.Now()
+	return false
+}
+
+func (s *notificationSubscriber) deleteMessage(msg *sqs.Message) {
+	_, err := s.sqsClient.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	})
+	if err != nil {
+		log.Printf("elastictranscoder: error deleting processed notification: %s", err)
+	}
+}
+
+// verifyNotificationTopics uses the ElasticTranscoder TestRole API to
+// confirm that the pipeline's IAM role is allowed to publish to the SNS
+// topics configured on the pipeline, failing fast at startup if the
+// notifications plumbing is misconfigured.
+func (p *awsProvider) verifyNotificationTopics(pipelineID string) error {
+	pipeline, err := p.c.ReadPipeline(&elastictranscoder.ReadPipelineInput{Id: aws.String(pipelineID)})
+	if err != nil {
+		return err
+	}
+	if pipeline.Pipeline == nil || pipeline.Pipeline.Notifications == nil {
+		return fmt.Errorf("pipeline %s has no notification topics configured", pipelineID)
+	}
+	pipelineNotifications := pipeline.Pipeline.Notifications
+	topics := []*string{}
+	for _, topic := range []*string{pipelineNotifications.Progressing, pipelineNotifications.Completed, pipelineNotifications.Warning, pipelineNotifications.Error} {
+		if aws.StringValue(topic) != "" {
+			topics = append(topics, topic)
+		}
+	}
+	_, err = p.c.TestRole(&elastictranscoder.TestRoleInput{
+		Role:         pipeline.Pipeline.Role,
+		InputBucket:  pipeline.Pipeline.InputBucket,
+		OutputBucket: pipeline.Pipeline.OutputBucket,
+		Topics:       topics,
+	})
+	return err
+}
@@ -0,0 +1,83 @@
+package elastictranscoder
+
+import (
+	"testing"
+
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// memRepo is a minimal in-memory db.Repository used to exercise
+// notificationSubscriber without a real storage backend.
+type memRepo struct {
+	jobs map[string]*db.Job
+}
+
+func newMemRepo(jobs ...*db.Job) *memRepo {
+	r := &memRepo{jobs: make(map[string]*db.Job)}
+	for _, job := range jobs {
+		r.jobs[job.ID] = job
+	}
+	return r
+}
+
+func (r *memRepo) GetJob(id string) (*db.Job, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, db.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (r *memRepo) SaveJob(job *db.Job) error {
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func TestApplyNotificationWarningDoesNotFailJob(t *testing.T) {
+	repo := newMemRepo(&db.Job{ID: "job-1", Status: string(provider.StatusStarted)})
+	s := &notificationSubscriber{repo: repo}
+
+	err := s.applyNotification(&etNotification{JobID: "job-1", State: "WARNING", MessageDetails: "disk space low"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	job, _ := repo.GetJob("job-1")
+	if job.Status != string(provider.StatusStarted) {
+		t.Errorf("got status %q, want %q", job.Status, provider.StatusStarted)
+	}
+	if job.ErrorMessage == "" {
+		t.Error("expected the warning's message to be recorded")
+	}
+}
+
+func TestApplyNotificationLateWarningDoesNotRegressFinishedJob(t *testing.T) {
+	repo := newMemRepo(&db.Job{ID: "job-1", Status: string(provider.StatusFinished)})
+	s := &notificationSubscriber{repo: repo}
+
+	err := s.applyNotification(&etNotification{JobID: "job-1", State: "WARNING", MessageDetails: "stray late warning"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	job, _ := repo.GetJob("job-1")
+	if job.Status != string(provider.StatusFinished) {
+		t.Errorf("got status %q, want %q (terminal status must not regress)", job.Status, provider.StatusFinished)
+	}
+}
+
+func TestApplyNotificationLateErrorDoesNotRegressFinishedJob(t *testing.T) {
+	repo := newMemRepo(&db.Job{ID: "job-1", Status: string(provider.StatusFinished)})
+	s := &notificationSubscriber{repo: repo}
+
+	err := s.applyNotification(&etNotification{JobID: "job-1", State: "ERROR", ErrorCode: 500})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	job, _ := repo.GetJob("job-1")
+	if job.Status != string(provider.StatusFinished) {
+		t.Errorf("got status %q, want %q (terminal status must not regress)", job.Status, provider.StatusFinished)
+	}
+}
@@ -0,0 +1,197 @@
+package elastictranscoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elastictranscoder"
+	"github.com/aws/aws-sdk-go/service/elastictranscoder/elastictranscoderiface"
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+func newTestProvider(pipelines ...config.Pipeline) *awsProvider {
+	return &awsProvider{config: &config.ElasticTranscoder{Pipelines: pipelines}}
+}
+
+// stubETClient is a minimal elastictranscoderiface.ElasticTranscoderAPI
+// that only implements the pipeline CRUD methods exercised by these
+// tests; any other method call panics on the embedded nil interface.
+type stubETClient struct {
+	elastictranscoderiface.ElasticTranscoderAPI
+	createdID string
+}
+
+func (s *stubETClient) CreatePipeline(input *elastictranscoder.CreatePipelineInput) (*elastictranscoder.CreatePipelineOutput, error) {
+	if input.OutputBucket != nil {
+		return nil, errors.New("elastictranscoder: OutputBucket may not be set together with ContentConfig")
+	}
+	if input.ContentConfig == nil || input.ThumbnailConfig == nil {
+		return nil, errors.New("elastictranscoder: ContentConfig and ThumbnailConfig must both be set")
+	}
+	return &elastictranscoder.CreatePipelineOutput{Pipeline: &elastictranscoder.Pipeline{Id: aws.String(s.createdID)}}, nil
+}
+
+func (s *stubETClient) UpdatePipeline(input *elastictranscoder.UpdatePipelineInput) (*elastictranscoder.UpdatePipelineOutput, error) {
+	if input.ContentConfig == nil || input.ThumbnailConfig == nil {
+		return nil, errors.New("elastictranscoder: ContentConfig and ThumbnailConfig must both be set")
+	}
+	return &elastictranscoder.UpdatePipelineOutput{}, nil
+}
+
+func (s *stubETClient) DeletePipeline(input *elastictranscoder.DeletePipelineInput) (*elastictranscoder.DeletePipelineOutput, error) {
+	return &elastictranscoder.DeletePipelineOutput{}, nil
+}
+
+func TestSelectPipelineByName(t *testing.T) {
+	p := newTestProvider(
+		config.Pipeline{Name: "default", ID: "pipeline-default"},
+		config.Pipeline{Name: "4k", ID: "pipeline-4k"},
+	)
+	pipeline, err := p.selectPipeline(provider.TranscodeProfile{PipelineName: "4k"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pipeline.ID != "pipeline-4k" {
+		t.Errorf("got pipeline %q, want %q", pipeline.ID, "pipeline-4k")
+	}
+}
+
+func TestSelectPipelineByNameNotFound(t *testing.T) {
+	p := newTestProvider(config.Pipeline{Name: "default", ID: "pipeline-default"})
+	_, err := p.selectPipeline(provider.TranscodeProfile{PipelineName: "missing"})
+	if err != provider.ErrPipelineNotFound {
+		t.Fatalf("got error %v, want %v", err, provider.ErrPipelineNotFound)
+	}
+}
+
+func TestSelectPipelineByInputBucket(t *testing.T) {
+	p := newTestProvider(
+		config.Pipeline{Name: "default", ID: "pipeline-default", InputBucket: "inputs-default"},
+		config.Pipeline{Name: "partner", ID: "pipeline-partner", InputBucket: "inputs-partner"},
+	)
+	pipeline, err := p.selectPipeline(provider.TranscodeProfile{SourceMedia: "s3://inputs-partner/videos/movie.mov"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pipeline.ID != "pipeline-partner" {
+		t.Errorf("got pipeline %q, want %q", pipeline.ID, "pipeline-partner")
+	}
+}
+
+func TestSelectPipelineRoundRobinFallback(t *testing.T) {
+	p := newTestProvider(
+		config.Pipeline{Name: "a", ID: "pipeline-a", InputBucket: "bucket-a"},
+		config.Pipeline{Name: "b", ID: "pipeline-b", InputBucket: "bucket-b"},
+	)
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		pipeline, err := p.selectPipeline(provider.TranscodeProfile{SourceMedia: "s3://no-match-bucket/movie.mov"})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		seen[pipeline.ID] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected round-robin to hit both pipelines over 4 calls, saw %v", seen)
+	}
+}
+
+func TestSelectPipelineNoneConfigured(t *testing.T) {
+	p := newTestProvider()
+	_, err := p.selectPipeline(provider.TranscodeProfile{SourceMedia: "s3://bucket/movie.mov"})
+	if err == nil {
+		t.Fatal("expected an error when no pipelines are configured")
+	}
+}
+
+func TestInputBucket(t *testing.T) {
+	p := newTestProvider()
+	tests := []struct {
+		source string
+		bucket string
+	}{
+		{"s3://my-bucket/path/to/video.mov", "my-bucket"},
+		{"s3://my-bucket", "my-bucket"},
+		{"/local/path/video.mov", ""},
+	}
+	for _, tt := range tests {
+		if got := p.inputBucket(tt.source); got != tt.bucket {
+			t.Errorf("inputBucket(%q) = %q, want %q", tt.source, got, tt.bucket)
+		}
+	}
+}
+
+func TestCreatePipelineAddsToLocalPool(t *testing.T) {
+	p := newTestProvider(config.Pipeline{Name: "default", ID: "pipeline-default"})
+	p.c = &stubETClient{createdID: "pipeline-new"}
+
+	id, err := p.CreatePipeline(provider.PipelineSpec{Name: "new", InputBucket: "inputs-new"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if id != "pipeline-new" {
+		t.Errorf("got id %q, want %q", id, "pipeline-new")
+	}
+
+	pipeline, err := p.pipelineByName("new")
+	if err != nil {
+		t.Fatalf("expected newly created pipeline to be immediately selectable, got error: %s", err)
+	}
+	if pipeline.InputBucket != "inputs-new" {
+		t.Errorf("got input bucket %q, want %q", pipeline.InputBucket, "inputs-new")
+	}
+}
+
+func TestCreatePipelineRequestShape(t *testing.T) {
+	p := newTestProvider()
+	p.c = &stubETClient{createdID: "pipeline-new"}
+
+	_, err := p.CreatePipeline(provider.PipelineSpec{
+		Name:         "new",
+		InputBucket:  "inputs-new",
+		OutputBucket: "outputs-new",
+		StorageClass: "ReducedRedundancy",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestUpdatePipelineUpdatesLocalPool(t *testing.T) {
+	p := newTestProvider(config.Pipeline{Name: "default", ID: "pipeline-default", InputBucket: "old-bucket"})
+	p.c = &stubETClient{}
+
+	err := p.UpdatePipeline("pipeline-default", provider.PipelineSpec{Name: "default", InputBucket: "new-bucket"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pipeline, err := p.pipelineByName("default")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pipeline.InputBucket != "new-bucket" {
+		t.Errorf("got input bucket %q, want %q", pipeline.InputBucket, "new-bucket")
+	}
+}
+
+func TestDeletePipelineRemovesFromLocalPool(t *testing.T) {
+	p := newTestProvider(
+		config.Pipeline{Name: "default", ID: "pipeline-default"},
+		config.Pipeline{Name: "extra", ID: "pipeline-extra"},
+	)
+	p.c = &stubETClient{}
+
+	if err := p.DeletePipeline("pipeline-extra"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := p.pipelineByName("extra"); err != provider.ErrPipelineNotFound {
+		t.Fatalf("expected removed pipeline to be unselectable, got: %v", err)
+	}
+	if _, err := p.pipelineByName("default"); err != nil {
+		t.Fatalf("expected unrelated pipeline to remain selectable, got error: %s", err)
+	}
+}
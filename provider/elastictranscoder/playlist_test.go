@@ -0,0 +1,122 @@
+package elastictranscoder
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+func TestCreatePlaylistsDefaultsToSingleHLSPlaylist(t *testing.T) {
+	p := &awsProvider{}
+	job := &db.Job{ID: "job-1"}
+	outputsByContainer := map[string][]db.PresetMap{
+		"ts": {{Name: "hls-720p"}, {Name: "hls-480p"}},
+	}
+
+	playlists := p.createPlaylists(job, "movie.mov", provider.StreamingParams{}, outputsByContainer)
+
+	if len(playlists) != 1 {
+		t.Fatalf("got %d playlists, want 1", len(playlists))
+	}
+	if got := aws.StringValue(playlists[0].Format); got != "HLSv3" {
+		t.Errorf("got format %q, want %q", got, "HLSv3")
+	}
+	if len(playlists[0].OutputKeys) != 2 {
+		t.Errorf("got %d output keys, want 2", len(playlists[0].OutputKeys))
+	}
+}
+
+func TestCreatePlaylistsGroupsByContainerPerProtocol(t *testing.T) {
+	p := &awsProvider{}
+	job := &db.Job{ID: "job-1"}
+	outputsByContainer := map[string][]db.PresetMap{
+		"ts":   {{Name: "hls-720p"}},
+		"fmp4": {{Name: "dash-720p"}, {Name: "dash-480p"}},
+	}
+
+	playlists := p.createPlaylists(job, "movie.mov", provider.StreamingParams{
+		Protocols: []string{"hls", "dash", "smooth"},
+	}, outputsByContainer)
+
+	// hls pulls from "ts", dash and smooth both pull from "fmp4", so all
+	// three protocols produce a playlist here.
+	if len(playlists) != 3 {
+		t.Fatalf("got %d playlists, want 3", len(playlists))
+	}
+	outputsByFormat := make(map[string]int)
+	names := make(map[string]bool)
+	for _, playlist := range playlists {
+		outputsByFormat[aws.StringValue(playlist.Format)] = len(playlist.OutputKeys)
+		name := aws.StringValue(playlist.Name)
+		if names[name] {
+			t.Errorf("got duplicate playlist name %q; CreateJob requires unique names within a job", name)
+		}
+		names[name] = true
+	}
+	want := map[string]int{"HLSv3": 1, "MPEG-DASH": 2, "Smooth": 2}
+	for format, count := range want {
+		if outputsByFormat[format] != count {
+			t.Errorf("got %d outputs for %s, want %d", outputsByFormat[format], format, count)
+		}
+	}
+}
+
+func TestCreatePlaylistsSkipsProtocolsWithNoMatchingOutputs(t *testing.T) {
+	p := &awsProvider{}
+	job := &db.Job{ID: "job-1"}
+	outputsByContainer := map[string][]db.PresetMap{
+		"ts": {{Name: "hls-720p"}},
+	}
+
+	playlists := p.createPlaylists(job, "movie.mov", provider.StreamingParams{
+		Protocols: []string{"hls", "dash"},
+	}, outputsByContainer)
+
+	if len(playlists) != 1 {
+		t.Fatalf("got %d playlists, want 1 (dash has no fmp4 outputs)", len(playlists))
+	}
+	if got := aws.StringValue(playlists[0].Format); got != "HLSv3" {
+		t.Errorf("got format %q, want %q", got, "HLSv3")
+	}
+}
+
+func TestCreatePlaylistsAppliesHLSContentProtectionOnlyToHLS(t *testing.T) {
+	p := &awsProvider{}
+	job := &db.Job{ID: "job-1"}
+	outputsByContainer := map[string][]db.PresetMap{
+		"ts":   {{Name: "hls-720p"}},
+		"fmp4": {{Name: "dash-720p"}},
+	}
+
+	playlists := p.createPlaylists(job, "movie.mov", provider.StreamingParams{
+		Protocols:            []string{"hls", "dash"},
+		HLSContentProtection: &provider.HLSContentProtection{Method: "aes-128", Key: "k"},
+	}, outputsByContainer)
+
+	for _, playlist := range playlists {
+		switch aws.StringValue(playlist.Format) {
+		case "HLSv3":
+			if playlist.HlsContentProtection == nil {
+				t.Error("expected HLS playlist to carry content protection")
+			}
+		case "MPEG-DASH":
+			if playlist.HlsContentProtection != nil {
+				t.Error("expected DASH playlist not to carry HLS content protection")
+			}
+		}
+	}
+}
+
+func TestCreatePlaylistsCustomName(t *testing.T) {
+	p := &awsProvider{}
+	job := &db.Job{ID: "job-1"}
+	outputsByContainer := map[string][]db.PresetMap{"ts": {{Name: "hls-720p"}}}
+
+	playlists := p.createPlaylists(job, "movie.mov", provider.StreamingParams{PlaylistName: "custom/master"}, outputsByContainer)
+
+	if got := aws.StringValue(playlists[0].Name); got != "custom/master" {
+		t.Errorf("got name %q, want %q", got, "custom/master")
+	}
+}
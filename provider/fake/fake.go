@@ -0,0 +1,166 @@
+// Package fake provides an in-memory implementation of
+// provider.TranscodingProvider, used to exercise the service layer and
+// other provider consumers in tests without talking to a real
+// transcoding backend.
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// Name is the name used for registering the fake provider in the registry
+// of providers.
+const Name = "fake"
+
+func init() {
+	provider.Register(Name, fakeProvider)
+}
+
+// Provider is an in-memory, non-persistent implementation of
+// provider.TranscodingProvider.
+type Provider struct {
+	mu        sync.Mutex
+	jobs      map[string]*provider.JobStatus
+	presets   map[string]provider.Preset
+	pipelines map[string]provider.PipelineSpec
+}
+
+// NewProvider returns a ready to use fake provider.
+func NewProvider() *Provider {
+	return &Provider{
+		jobs:      make(map[string]*provider.JobStatus),
+		presets:   make(map[string]provider.Preset),
+		pipelines: make(map[string]provider.PipelineSpec),
+	}
+}
+
+// Transcode registers a new job in the Queued state and returns its
+// status.
+func (p *Provider) Transcode(job *db.Job, transcodeProfile provider.TranscodeProfile) (*provider.JobStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := &provider.JobStatus{
+		ProviderName:  Name,
+		ProviderJobID: job.ID,
+		Status:        provider.StatusQueued,
+	}
+	p.jobs[job.ID] = status
+	return status, nil
+}
+
+// JobStatus returns the current status of the given job.
+func (p *Provider) JobStatus(id string) (*provider.JobStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status, ok := p.jobs[id]
+	if !ok {
+		return nil, errNotFound(id)
+	}
+	statusCopy := *status
+	return &statusCopy, nil
+}
+
+// CancelJob cancels a job that is still queued, mirroring the rule
+// enforced by the Elastic Transcoder provider: a job that has already
+// started progressing, finished, or was already canceled can't be
+// canceled again.
+func (p *Provider) CancelJob(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status, ok := p.jobs[id]
+	if !ok {
+		return errNotFound(id)
+	}
+	if status.Status != provider.StatusQueued {
+		return provider.ErrJobNotCancellable
+	}
+	status.Status = provider.StatusCanceled
+	return nil
+}
+
+// CreatePreset stores preset and returns its name as its ID.
+func (p *Provider) CreatePreset(preset provider.Preset) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.presets[preset.Name] = preset
+	return preset.Name, nil
+}
+
+// GetPreset returns the preset previously stored under presetID.
+func (p *Provider) GetPreset(presetID string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	preset, ok := p.presets[presetID]
+	if !ok {
+		return nil, errNotFound(presetID)
+	}
+	return preset, nil
+}
+
+// DeletePreset removes the preset stored under presetID.
+func (p *Provider) DeletePreset(presetID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.presets, presetID)
+	return nil
+}
+
+// CreatePipeline stores spec under its Name, which it returns as the
+// pipeline's ID.
+func (p *Provider) CreatePipeline(spec provider.PipelineSpec) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := spec.Name
+	p.pipelines[id] = spec
+	return id, nil
+}
+
+// UpdatePipeline replaces the spec stored under id.
+func (p *Provider) UpdatePipeline(id string, spec provider.PipelineSpec) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pipelines[id]; !ok {
+		return provider.ErrPipelineNotFound
+	}
+	p.pipelines[id] = spec
+	return nil
+}
+
+// DeletePipeline removes the pipeline stored under id.
+func (p *Provider) DeletePipeline(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pipelines[id]; !ok {
+		return provider.ErrPipelineNotFound
+	}
+	delete(p.pipelines, id)
+	return nil
+}
+
+// Healthcheck always reports the fake provider as healthy.
+func (p *Provider) Healthcheck() error {
+	return nil
+}
+
+// Capabilities returns a permissive set of capabilities, since the fake
+// provider doesn't actually transcode anything.
+func (p *Provider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{
+		InputFormats:  []string{"h264", "vp8", "vp9"},
+		OutputFormats: []string{"mp4", "hls", "webm"},
+		Destinations:  []string{"s3"},
+	}
+}
+
+func fakeProvider(cfg *config.Config) (provider.TranscodingProvider, error) {
+	return NewProvider(), nil
+}
+
+func errNotFound(id string) error {
+	return fmt.Errorf("fake: not found: %s", id)
+}
@@ -0,0 +1,35 @@
+// Package notifications defines a generic interface for providers that can
+// push asynchronous job status updates (as opposed to being polled for
+// status via provider.TranscodingProvider.JobStatus).
+package notifications
+
+import "github.com/nytm/video-transcoding-api/provider"
+
+// Event represents a single, provider-agnostic job status update.
+type Event struct {
+	ProviderJobID string
+	ProviderName  string
+	Status        provider.Status
+	Outputs       []OutputEvent
+	ErrorMessage  string
+}
+
+// OutputEvent carries the status of a single output within a job.
+type OutputEvent struct {
+	Key          string
+	Status       string
+	StatusDetail string
+}
+
+// Subscriber consumes asynchronous job status notifications pushed by a
+// provider and applies them to the job store, so the API no longer needs
+// to poll the provider for status.
+type Subscriber interface {
+	// Start begins consuming notifications. It blocks until Stop is
+	// called or an unrecoverable error occurs.
+	Start() error
+
+	// Stop gracefully stops consuming notifications, waiting for
+	// in-flight messages to finish processing.
+	Stop() error
+}
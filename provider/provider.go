@@ -0,0 +1,307 @@
+// Package provider defines interfaces and types to be implemented by
+// providers of the video-transcoding-api.
+package provider
+
+import (
+	"errors"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+// Status is the status of a transcoding job.
+type Status string
+
+const (
+	// StatusQueued is the status for a job that is queued on the provider
+	// but hasn't started transcoding yet.
+	StatusQueued = Status("queued")
+
+	// StatusStarted is the status for a job that is currently transcoding.
+	StatusStarted = Status("started")
+
+	// StatusFinished is the status for a job that has finished
+	// transcoding, successfully.
+	StatusFinished = Status("finished")
+
+	// StatusFailed is the status for a job that has failed to transcode.
+	StatusFailed = Status("failed")
+
+	// StatusCanceled is the status for a job that was canceled before it
+	// finished transcoding.
+	StatusCanceled = Status("canceled")
+)
+
+var (
+	// ErrPresetMapNotFound is the error returned when the preset map is not
+	// found.
+	ErrPresetMapNotFound = errors.New("preset map not found")
+
+	// ErrProviderNotFound is the error returned when the provider is not
+	// found in the registry.
+	ErrProviderNotFound = errors.New("provider not found")
+
+	// ErrJobNotCancellable is the error returned when a cancelation is
+	// requested for a job that is not in a cancellable state (it has
+	// already started progressing, finished, or was already canceled).
+	ErrJobNotCancellable = errors.New("job can't be canceled, as it's either finished or being processed")
+
+	// ErrPipelineNotFound is the error returned when a requested pipeline
+	// name does not match any of the provider's configured pipelines.
+	ErrPipelineNotFound = errors.New("pipeline not found")
+)
+
+// JobStatus is the representation of the status of a given job, returned
+// when querying its current state in the provider.
+type JobStatus struct {
+	ProviderJobID     string                 `json:"providerJobId,omitempty"`
+	ProviderName      string                 `json:"providerName,omitempty"`
+	Status            Status                 `json:"status,omitempty"`
+	ProviderStatus    map[string]interface{} `json:"providerStatus,omitempty"`
+	OutputDestination string                 `json:"outputDestination,omitempty"`
+}
+
+// Capabilities represents a given provider's capabilities in terms of
+// formats it can accept as input and output, and destinations it can send
+// the output to.
+type Capabilities struct {
+	InputFormats  []string
+	OutputFormats []string
+	Destinations  []string
+}
+
+// VideoPreset represents the video portion of a preset.
+type VideoPreset struct {
+	Profile              string
+	ProfileLevel         string
+	Width                string
+	Height               string
+	Codec                string
+	Bitrate              string
+	GopSize              string
+	GopMode              string
+	MaxReferenceFrames   string
+	MaxBitRate           string
+	BufferSize           string
+	InterlacedMode       string
+	ColorSpaceConversion string
+
+	// CodecOptions carries any additional, codec-specific options (e.g.
+	// VP8/VP9-only knobs) that don't warrant a dedicated field.
+	CodecOptions map[string]string
+}
+
+// AudioPreset represents the audio portion of a preset.
+type AudioPreset struct {
+	Codec    string
+	Bitrate  string
+	Profile  string
+	BitDepth string
+	BitOrder string
+	Signed   string
+}
+
+// Watermark represents a single image overlay applied to a video preset's
+// output.
+type Watermark struct {
+	ID               string
+	MaxWidth         string
+	MaxHeight        string
+	HorizontalAlign  string
+	HorizontalOffset string
+	VerticalAlign    string
+	VerticalOffset   string
+	Opacity          string
+	Target           string
+}
+
+// ThumbnailsPreset represents the thumbnail extraction portion of a
+// preset.
+type ThumbnailsPreset struct {
+	Interval      string
+	Format        string
+	Resolution    string
+	SizingPolicy  string
+	PaddingPolicy string
+}
+
+// Encryption describes how a given input or output artifact is (or should
+// be) encrypted.
+type Encryption struct {
+	Mode                 string
+	Key                  string
+	KeyMd5               string
+	InitializationVector string
+}
+
+// CaptionSource represents a single input caption file to be merged into
+// the job's outputs.
+type CaptionSource struct {
+	Key        string
+	Language   string
+	TimeOffset string
+	Label      string
+	Encryption *Encryption
+}
+
+// CaptionFormat represents a single output caption format to be generated
+// from the job's CaptionSources.
+type CaptionFormat struct {
+	Format     string
+	Pattern    string
+	Encryption *Encryption
+}
+
+// Captions groups the caption sources and output formats for a preset.
+type Captions struct {
+	MergePolicy    string
+	CaptionSources []CaptionSource
+	CaptionFormats []CaptionFormat
+}
+
+// Preset is the generic representation of a preset, sent to providers so
+// they can create their own provider-specific preset.
+type Preset struct {
+	Name         string
+	Description  string
+	Container    string
+	Profile      string
+	ProfileLevel string
+	Video        VideoPreset
+	Audio        AudioPreset
+	Watermarks   []Watermark
+	Thumbnails   ThumbnailsPreset
+	Captions     *Captions
+	Encryption   *Encryption
+}
+
+// HLSContentProtection describes the AES encryption applied to an HLS
+// playlist's segments.
+type HLSContentProtection struct {
+	Method                string
+	Key                   string
+	KeyMd5                string
+	InitializationVector  string
+	LicenseAcquisitionURL string
+	KeyStoragePolicy      string
+}
+
+// PlayReadyDRM describes the Microsoft PlayReady DRM applied to a Smooth
+// Streaming or MPEG-DASH playlist.
+type PlayReadyDRM struct {
+	Format                string
+	Key                   string
+	KeyID                 string
+	KeyMd5                string
+	LicenseAcquisitionURL string
+	InitializationVector  string
+}
+
+// StreamingParams represents the parameters for generating adaptive
+// streaming playlists out of a set of outputs.
+type StreamingParams struct {
+	SegmentDuration uint
+
+	// Protocols is the list of adaptive streaming protocols to generate a
+	// playlist for, e.g. []string{"hls", "dash", "smooth"}. When empty,
+	// a single HLS playlist is generated for backwards compatibility, if
+	// the job has "ts" outputs. A protocol whose container has no
+	// outputs in the job gets no playlist.
+	Protocols []string
+
+	// PlaylistName overrides the default, job-derived name used for the
+	// generated playlist(s).
+	PlaylistName string
+
+	HLSContentProtection *HLSContentProtection
+	PlayReadyDRM         *PlayReadyDRM
+}
+
+// TranscodeProfile is the profile sent to a provider in order to transcode
+// a given job.
+type TranscodeProfile struct {
+	SourceMedia     string
+	Presets         []db.PresetMap
+	StreamingParams StreamingParams
+
+	// PipelineName optionally selects which of the provider's configured
+	// pipelines the job should run through. When empty, the provider
+	// picks a pipeline by matching SourceMedia's input bucket, falling
+	// back to round-robin.
+	PipelineName string
+}
+
+// PipelineSpec describes a pipeline to be created or updated in a
+// provider that supports pipeline management.
+type PipelineSpec struct {
+	Name          string
+	InputBucket   string
+	OutputBucket  string
+	Role          string
+	StorageClass  string
+	Notifications bool
+}
+
+// TranscodingProvider represents the interface to be implemented by all
+// video transcoding providers supported by the API.
+type TranscodingProvider interface {
+	// Transcode sends a job to the provider's API.
+	Transcode(job *db.Job, transcodeProfile TranscodeProfile) (*JobStatus, error)
+
+	// JobStatus returns the status of a given job.
+	JobStatus(id string) (*JobStatus, error)
+
+	// CancelJob cancels a job that is still queued (has not started
+	// progressing yet) on the provider. It returns ErrJobNotCancellable
+	// when the job is no longer in a cancellable state.
+	CancelJob(id string) error
+
+	// CreatePreset creates a preset in the provider.
+	CreatePreset(preset Preset) (string, error)
+
+	// GetPreset gets the preset information, given its ID.
+	GetPreset(presetID string) (interface{}, error)
+
+	// DeletePreset removes a preset from the provider.
+	DeletePreset(presetID string) error
+
+	// CreatePipeline creates a new pipeline in the provider and returns
+	// its ID.
+	CreatePipeline(spec PipelineSpec) (string, error)
+
+	// UpdatePipeline updates an existing pipeline in the provider.
+	UpdatePipeline(id string, spec PipelineSpec) error
+
+	// DeletePipeline removes a pipeline from the provider.
+	DeletePipeline(id string) error
+
+	// Healthcheck should return nil if the provider is currently
+	// operational, or an error describing the issue seen.
+	Healthcheck() error
+
+	// Capabilities describes the capabilities of the provider.
+	Capabilities() Capabilities
+}
+
+// Factory is the function responsible for creating the instance of a
+// provider.
+type Factory func(cfg *config.Config) (TranscodingProvider, error)
+
+var providers = make(map[string]Factory)
+
+// Register registers a new provider in the internal registry of providers.
+func Register(name string, factory Factory) error {
+	providers[name] = factory
+	return nil
+}
+
+// GetProviderFactory looks up the registry for the factory of the given
+// name and returns it, or ErrProviderNotFound if no such factory is
+// registered.
+func GetProviderFactory(name string) (Factory, error) {
+	factory, ok := providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return factory, nil
+}
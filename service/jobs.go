@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// handleJobs dispatches requests under /jobs/.
+func (s *Service) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.cancelJob(w, r)
+}
+
+// cancelJob handles DELETE /jobs/{id}, canceling the job with the given id
+// on the provider it was submitted to. It responds with 404 if the job is
+// unknown, 409 if the job is no longer in a cancellable state, and 204 on
+// success.
+func (s *Service) cancelJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if id == "" {
+		http.Error(w, "missing job id", http.StatusBadRequest)
+		return
+	}
+	job, err := s.repo.GetJob(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	p, err := s.providerNamed(job.ProviderName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := p.CancelJob(job.ProviderJobID); err != nil {
+		status := http.StatusInternalServerError
+		if err == provider.ErrJobNotCancellable {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err)
+		return
+	}
+	job.Status = string(provider.StatusCanceled)
+	if err := s.repo.SaveJob(job); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
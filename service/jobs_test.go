@@ -0,0 +1,101 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+	"github.com/nytm/video-transcoding-api/provider/fake"
+)
+
+// memRepo is a minimal in-memory db.Repository used to exercise Service
+// without a real storage backend.
+type memRepo struct {
+	jobs map[string]*db.Job
+}
+
+func newMemRepo(jobs ...*db.Job) *memRepo {
+	r := &memRepo{jobs: make(map[string]*db.Job)}
+	for _, job := range jobs {
+		r.jobs[job.ID] = job
+	}
+	return r
+}
+
+func (r *memRepo) GetJob(id string) (*db.Job, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, db.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (r *memRepo) SaveJob(job *db.Job) error {
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func TestCancelJob(t *testing.T) {
+	p := fake.NewProvider()
+	p.Transcode(&db.Job{ID: "job-1"}, provider.TranscodeProfile{})
+	repo := newMemRepo(&db.Job{ID: "job-1", ProviderName: fake.Name, ProviderJobID: "job-1", Status: string(provider.StatusQueued)})
+	svc := NewService(repo, map[string]provider.TranscodingProvider{fake.Name: p})
+
+	req := httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	job, err := repo.GetJob("job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.Status != string(provider.StatusCanceled) {
+		t.Errorf("got status %q, want %q", job.Status, provider.StatusCanceled)
+	}
+}
+
+func TestCancelJobNotCancellable(t *testing.T) {
+	p := fake.NewProvider()
+	p.Transcode(&db.Job{ID: "job-1"}, provider.TranscodeProfile{})
+	p.CancelJob("job-1")
+	repo := newMemRepo(&db.Job{ID: "job-1", ProviderName: fake.Name, ProviderJobID: "job-1", Status: string(provider.StatusCanceled)})
+	svc := NewService(repo, map[string]provider.TranscodingProvider{fake.Name: p})
+
+	req := httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestCancelJobNotFound(t *testing.T) {
+	repo := newMemRepo()
+	svc := NewService(repo, map[string]provider.TranscodingProvider{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/jobs/missing", nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestCancelJobMethodNotAllowed(t *testing.T) {
+	svc := NewService(newMemRepo(), map[string]provider.TranscodingProvider{})
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// pipelineRequest is the JSON body accepted by the pipeline admin
+// endpoints. ProviderName selects which provider's pipeline pool the
+// request targets.
+type pipelineRequest struct {
+	ProviderName  string `json:"providerName"`
+	Name          string `json:"name"`
+	InputBucket   string `json:"inputBucket"`
+	OutputBucket  string `json:"outputBucket"`
+	Role          string `json:"role"`
+	StorageClass  string `json:"storageClass"`
+	Notifications bool   `json:"notifications"`
+}
+
+func (r pipelineRequest) spec() provider.PipelineSpec {
+	return provider.PipelineSpec{
+		Name:          r.Name,
+		InputBucket:   r.InputBucket,
+		OutputBucket:  r.OutputBucket,
+		Role:          r.Role,
+		StorageClass:  r.StorageClass,
+		Notifications: r.Notifications,
+	}
+}
+
+// handlePipelines dispatches requests to create a pipeline.
+func (s *Service) handlePipelines(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.createPipeline(w, r)
+}
+
+// handlePipelineByID dispatches requests under /pipelines/{id}.
+func (s *Service) handlePipelineByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		s.updatePipeline(w, r)
+	case http.MethodDelete:
+		s.deletePipeline(w, r)
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createPipeline handles POST /pipelines, creating a new pipeline on the
+// named provider and responding with its generated ID.
+func (s *Service) createPipeline(w http.ResponseWriter, r *http.Request) {
+	var req pipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := s.providerNamed(req.ProviderName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id, err := p.CreatePipeline(req.spec())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"pipelineId": id})
+}
+
+// updatePipeline handles PUT /pipelines/{id}, replacing the pipeline's
+// configuration on the named provider.
+func (s *Service) updatePipeline(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/pipelines/")
+	if id == "" {
+		http.Error(w, "missing pipeline id", http.StatusBadRequest)
+		return
+	}
+	var req pipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p, err := s.providerNamed(req.ProviderName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := p.UpdatePipeline(id, req.spec()); err != nil {
+		writeError(w, pipelineErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deletePipeline handles DELETE /pipelines/{id}?provider={providerName},
+// removing the pipeline from the named provider.
+func (s *Service) deletePipeline(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/pipelines/")
+	if id == "" {
+		http.Error(w, "missing pipeline id", http.StatusBadRequest)
+		return
+	}
+	p, err := s.providerNamed(r.URL.Query().Get("provider"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := p.DeletePipeline(id); err != nil {
+		writeError(w, pipelineErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pipelineErrorStatus maps a pipeline provider error to the HTTP status
+// that best represents it.
+func pipelineErrorStatus(err error) int {
+	if err == provider.ErrPipelineNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
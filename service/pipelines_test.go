@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nytm/video-transcoding-api/provider"
+	"github.com/nytm/video-transcoding-api/provider/fake"
+)
+
+func TestCreatePipeline(t *testing.T) {
+	p := fake.NewProvider()
+	svc := NewService(newMemRepo(), map[string]provider.TranscodingProvider{fake.Name: p})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"providerName": fake.Name,
+		"name":         "new-pipeline",
+		"inputBucket":  "inputs",
+		"outputBucket": "outputs",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/pipelines", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusCreated)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unexpected error decoding response: %s", err)
+	}
+	if resp["pipelineId"] != "new-pipeline" {
+		t.Errorf("got pipelineId %q, want %q", resp["pipelineId"], "new-pipeline")
+	}
+}
+
+func TestUpdatePipelineNotFound(t *testing.T) {
+	p := fake.NewProvider()
+	svc := NewService(newMemRepo(), map[string]provider.TranscodingProvider{fake.Name: p})
+
+	body, _ := json.Marshal(map[string]interface{}{"providerName": fake.Name, "name": "missing"})
+	req := httptest.NewRequest(http.MethodPut, "/pipelines/missing", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeletePipeline(t *testing.T) {
+	p := fake.NewProvider()
+	p.CreatePipeline(provider.PipelineSpec{Name: "doomed"})
+	svc := NewService(newMemRepo(), map[string]provider.TranscodingProvider{fake.Name: p})
+
+	req := httptest.NewRequest(http.MethodDelete, "/pipelines/doomed?provider="+fake.Name, nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if err := p.DeletePipeline("doomed"); err != provider.ErrPipelineNotFound {
+		t.Fatalf("expected pipeline to already be gone, got: %v", err)
+	}
+}
+
+func TestPipelinesMethodNotAllowed(t *testing.T) {
+	svc := NewService(newMemRepo(), map[string]provider.TranscodingProvider{})
+
+	req := httptest.NewRequest(http.MethodGet, "/pipelines", nil)
+	w := httptest.NewRecorder()
+	svc.NewRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
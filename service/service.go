@@ -0,0 +1,50 @@
+// Package service exposes the video-transcoding-api's provider operations
+// over HTTP: job lifecycle management and, where a provider supports it,
+// pipeline administration.
+package service
+
+import (
+	"net/http"
+
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// Service routes HTTP requests to the provider responsible for a given job
+// or pipeline, and keeps db.Repository in sync with the outcome.
+type Service struct {
+	repo      db.Repository
+	providers map[string]provider.TranscodingProvider
+}
+
+// NewService returns a Service that dispatches to the given already
+// constructed providers, keyed by provider.TranscodingProvider name (e.g.
+// elastictranscoder.Name), and persists job state through repo.
+func NewService(repo db.Repository, providers map[string]provider.TranscodingProvider) *Service {
+	return &Service{repo: repo, providers: providers}
+}
+
+// providerNamed looks up a constructed provider by name, returning
+// provider.ErrProviderNotFound if none is registered under that name.
+func (s *Service) providerNamed(name string) (provider.TranscodingProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, provider.ErrProviderNotFound
+	}
+	return p, nil
+}
+
+// NewRouter returns an http.Handler that wires up every endpoint exposed by
+// Service.
+func (s *Service) NewRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/", s.handleJobs)
+	mux.HandleFunc("/pipelines", s.handlePipelines)
+	mux.HandleFunc("/pipelines/", s.handlePipelineByID)
+	return mux
+}
+
+// writeError writes err's message as the HTTP response body with status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	http.Error(w, err.Error(), status)
+}